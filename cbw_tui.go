@@ -3,49 +3,101 @@ package main
 Name:        cbw_tui.go
 Date:        2025-10-02
 Script Name: cbw_tui.go
-Version:     1.0
+Version:     1.2
 Log Summary: Simple Bubble Tea TUI that watches bulk_urls.json and retry_report.json and displays status.
 Description: Minimal TUI to monitor discovery results and retry counts. Use 'go build' to compile.
+  Also supports --mode=http for a one-line status readout from the pipeline's control API.
 Change Summary:
   - 1.0 initial watch-mode TUI.
+  - 1.1 added --mode=http, backed by the control package client.
+  - 1.2 threaded a cancellable context through Init/Update; Ctrl-C now cancels it so the
+    in-flight status poll (http mode) is aborted instead of leaking past program exit.
 Inputs:
   - flags: --watch-dir (directory containing bulk_urls.json and retry_report.json)
+  - flags: --mode (watch|http), --api-url (when mode=http)
 Outputs:
   - interactive terminal UI showing counts and sample urls
 */
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cbwinslow/opengovt/control"
 )
 
 type model struct {
+	mode       string
 	watchDir   string
+	apiURL     string
 	bulk       []string
 	retryCount int
 	logTail    string
 	err        error
+
+	client *control.Client
+	status control.StatusResponse
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type statusMsg struct {
+	status control.StatusResponse
+	err    error
 }
 
 func readJSON(path string) ([]byte, error) {
 	return ioutil.ReadFile(path)
 }
 
-func newModel(watchDir string) model {
-	return model{watchDir: watchDir}
+func newModel(ctx context.Context, cancel context.CancelFunc, mode, watchDir, apiURL string) model {
+	m := model{mode: mode, watchDir: watchDir, apiURL: apiURL, ctx: ctx, cancel: cancel}
+	if mode == "http" {
+		m.client = control.NewClient(apiURL)
+	}
+	return m
 }
 
 func (m model) Init() tea.Cmd {
+	if m.mode == "http" {
+		return m.pollStatus()
+	}
 	return tea.Tick(time.Second*2, func(t time.Time) tea.Msg { return t })
 }
 
+func (m model) pollStatus() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+		defer cancel()
+		st, err := m.client.Status(ctx)
+		return statusMsg{status: st, err: err}
+	}
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg.(type) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "q" {
+			m.cancel()
+			return m, tea.Quit
+		}
+		return m, nil
+	case statusMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.status = msg.status
+		}
+		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return m.pollStatus()() })
 	case time.Time:
 		bulkPath := filepath.Join(m.watchDir, "bulk_urls.json")
 		retryPath := filepath.Join(m.watchDir, "retry_report.json")
@@ -73,6 +125,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	s := "cbw Congress Pipeline TUI\n\n"
+	if m.mode == "http" {
+		s += fmt.Sprintf("API: %s\n", m.apiURL)
+		s += fmt.Sprintf("State: %s\n", m.status.State)
+		s += fmt.Sprintf("Discovered: %d  Downloaded: %d  Failed: %d\n",
+			m.status.DiscoveredURLs, m.status.Downloaded, m.status.Failed)
+		if m.err != nil {
+			s += fmt.Sprintf("error: %v\n", m.err)
+		}
+		s += "\nPress Ctrl-C to quit.\n"
+		return s
+	}
 	s += fmt.Sprintf("Discovered URLs: %d\n", len(m.bulk))
 	s += fmt.Sprintf("Retry failures: %d\n\n", m.retryCount)
 	s += "Sample URLs:\n"
@@ -87,9 +150,21 @@ func (m model) View() string {
 }
 
 func main() {
+	mode := flag.String("mode", "watch", "mode: watch or http")
 	watchDir := flag.String("watch-dir", ".", "directory to watch for bulk_urls.json and retry_report.json")
+	apiURL := flag.String("api-url", "http://localhost:8080", "HTTP control API URL (when mode=http)")
 	flag.Parse()
-	p := tea.NewProgram(newModel(*watchDir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	p := tea.NewProgram(newModel(ctx, cancel, *mode, *watchDir, *apiURL), tea.WithContext(ctx))
 	if err := p.Start(); err != nil {
 		fmt.Println("Error starting TUI:", err)
 	}
@@ -0,0 +1,161 @@
+package control
+
+/*
+Name:        control/client.go
+Date:        2025-10-03
+Script Name: client.go
+Version:     1.1
+Log Summary: Go client for the pipeline HTTP control API, used by the TUI's Update loop.
+Description: Thin wrapper around net/http that speaks the same wire types as
+  control/server.go. Every method takes a context so callers (the TUI's tea.Cmd
+  goroutines) can cancel in-flight requests when the program exits.
+Change Summary:
+  - 1.0 initial client with SSE log tailing.
+  - 1.1 Logs now uses its own *http.Client with no Timeout instead of sharing the
+    unary client's 10s Timeout, which was force-closing the /logs stream after 10s
+    regardless of ctx; streaming now relies on ctx alone for cancellation.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to a pipeline's control-plane HTTP server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	stream  *http.Client
+}
+
+// NewClient returns a Client pointed at baseURL (e.g. "http://localhost:8080").
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+		// The 10s timeout above bounds an entire request including body reads, which
+		// would force-close the long-lived /logs SSE stream; Logs relies solely on
+		// ctx for cancellation instead.
+		stream: &http.Client{Timeout: 0},
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reqBody *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	} else {
+		reqBody = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if e.Error != "" {
+			return fmt.Errorf("control: %s %s: %s", method, path, e.Error)
+		}
+		return fmt.Errorf("control: %s %s: status %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Status fetches the pipeline's current status.
+func (c *Client) Status(ctx context.Context) (StatusResponse, error) {
+	var out StatusResponse
+	err := c.do(ctx, http.MethodGet, "/status", nil, &out)
+	return out, err
+}
+
+// Start kicks off a pipeline run, optionally scoped to specific sources.
+// An empty StartRequest triggers a full bulk-URL re-discovery.
+func (c *Client) Start(ctx context.Context, req StartRequest) (StartResponse, error) {
+	var out StartResponse
+	err := c.do(ctx, http.MethodPost, "/start", req, &out)
+	return out, err
+}
+
+// Stop halts the pipeline run.
+func (c *Client) Stop(ctx context.Context, req StopRequest) (StopResponse, error) {
+	var out StopResponse
+	err := c.do(ctx, http.MethodPost, "/stop", req, &out)
+	return out, err
+}
+
+// Pause suspends the pipeline run without cancelling in-flight jobs.
+func (c *Client) Pause(ctx context.Context) (PauseResponse, error) {
+	var out PauseResponse
+	err := c.do(ctx, http.MethodPost, "/pause", nil, &out)
+	return out, err
+}
+
+// Resume continues a paused pipeline run.
+func (c *Client) Resume(ctx context.Context) (ResumeResponse, error) {
+	var out ResumeResponse
+	err := c.do(ctx, http.MethodPost, "/resume", nil, &out)
+	return out, err
+}
+
+// Retry re-queues failed URLs, or every known failure if req.URLs is empty.
+func (c *Client) Retry(ctx context.Context, req RetryRequest) (RetryResponse, error) {
+	var out RetryResponse
+	err := c.do(ctx, http.MethodPost, "/retry", req, &out)
+	return out, err
+}
+
+// Logs subscribes to the /logs SSE stream starting after sinceNano, calling recv for
+// each entry until ctx is cancelled or the connection drops. It blocks, so callers
+// should run it in its own goroutine (e.g. from a tea.Cmd).
+func (c *Client) Logs(ctx context.Context, sinceNano int64, recv func(LogEntry)) error {
+	path := fmt.Sprintf("/logs?since=%d", sinceNano)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("control: GET %s: status %d", path, resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &entry); err != nil {
+			continue
+		}
+		recv(entry)
+	}
+	return scanner.Err()
+}
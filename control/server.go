@@ -0,0 +1,171 @@
+package control
+
+/*
+Name:        control/server.go
+Date:        2025-10-03
+Script Name: server.go
+Version:     1.0
+Log Summary: HTTP control-plane server embedded in the pipeline process.
+Description: Exposes /status, /start, /stop, /pause, /resume, /retry and a /logs SSE
+  stream over plain JSON HTTP. The server itself holds no pipeline state; it delegates
+  to a Pipeline implementation so the pipeline package stays the single source of truth.
+Change Summary:
+  - 1.0 initial server with Pipeline delegation and SSE log tailing.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Pipeline is the subset of pipeline behavior the control server needs. The pipeline
+// package implements this; the server never reaches into pipeline internals directly.
+type Pipeline interface {
+	Status(ctx context.Context) (StatusResponse, error)
+	Start(ctx context.Context, req StartRequest) (StartResponse, error)
+	Stop(ctx context.Context, req StopRequest) (StopResponse, error)
+	Pause(ctx context.Context) (PauseResponse, error)
+	Resume(ctx context.Context) (ResumeResponse, error)
+	Retry(ctx context.Context, req RetryRequest) (RetryResponse, error)
+	// Logs streams log entries with Time after since to the callback until ctx is done.
+	Logs(ctx context.Context, since int64, send func(LogEntry) error) error
+}
+
+// Server wraps a Pipeline with the HTTP control-plane routes.
+type Server struct {
+	pipeline Pipeline
+}
+
+// NewServer returns a control-plane Server backed by the given Pipeline.
+func NewServer(p Pipeline) *Server {
+	return &Server{pipeline: p}
+}
+
+// Handler returns the http.Handler to mount, e.g. on an http.Server or in tests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/start", s.handleStart)
+	mux.HandleFunc("/stop", s.handleStop)
+	mux.HandleFunc("/pause", s.handlePause)
+	mux.HandleFunc("/resume", s.handleResume)
+	mux.HandleFunc("/retry", s.handleRetry)
+	mux.HandleFunc("/logs", s.handleLogs)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErr(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.pipeline.Status(r.Context())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+	resp, err := s.pipeline.Start(r.Context(), req)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	var req StopRequest
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+	resp, err := s.pipeline.Stop(r.Context(), req)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.pipeline.Pause(r.Context())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.pipeline.Resume(r.Context())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleRetry(w http.ResponseWriter, r *http.Request) {
+	var req RetryRequest
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+	resp, err := s.pipeline.Retry(r.Context(), req)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleLogs streams LogEntry records as Server-Sent Events, starting after the cursor
+// supplied via ?since=<unix-nano>, so a reconnecting client can resume where it left off.
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErr(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+	var since int64
+	if v := r.URL.Query().Get("since"); v != "" {
+		_, _ = fmt.Sscanf(v, "%d", &since)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	err := s.pipeline.Logs(r.Context(), since, func(entry LogEntry) error {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && r.Context().Err() == nil {
+		// Best-effort: the stream is already open, so report via a final event rather
+		// than an HTTP status code.
+		b, _ := json.Marshal(map[string]string{"error": err.Error()})
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", b)
+		flusher.Flush()
+	}
+}
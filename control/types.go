@@ -0,0 +1,98 @@
+package control
+
+/*
+Name:        control/types.go
+Date:        2025-10-03
+Script Name: types.go
+Version:     1.0
+Log Summary: Wire types shared between the pipeline HTTP control server and its Go client.
+Description: Mirrors the request/response split containerd uses in api/grpc/types so the
+  server and client never drift: each route has at most one request type (status, pause
+  and resume take none) and one response type (/logs streams LogEntry records), all plain
+  JSON-tagged structs with no server-only or client-only fields.
+Change Summary:
+  - 1.0 initial types for status/start/stop/pause/resume/retry/logs.
+*/
+
+import "time"
+
+// State is the pipeline's coarse run state, as reported by /status.
+type State string
+
+const (
+	StateIdle    State = "idle"
+	StateRunning State = "running"
+	StatePaused  State = "paused"
+	StateStopped State = "stopped"
+	StateError   State = "error"
+)
+
+// StatusResponse is returned by GET /status.
+type StatusResponse struct {
+	State          State     `json:"state"`
+	DiscoveredURLs int       `json:"discovered_urls"`
+	Downloaded     int       `json:"downloaded"`
+	Failed         int       `json:"failed"`
+	StartedAt      time.Time `json:"started_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	LastError      string    `json:"last_error,omitempty"`
+}
+
+// StartRequest is sent to POST /start. An empty Sources slice means
+// "rediscover everything", matching the bulk-URL re-discovery path the TUI triggers.
+type StartRequest struct {
+	Sources []string `json:"sources,omitempty"`
+}
+
+// StartResponse acknowledges a start request; JobID identifies the run for /status polling.
+type StartResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// StopRequest is sent to POST /stop. Force skips graceful drain of in-flight jobs.
+type StopRequest struct {
+	Force bool `json:"force,omitempty"`
+}
+
+// StopResponse acknowledges a stop request.
+type StopResponse struct {
+	Stopped bool `json:"stopped"`
+}
+
+// PauseResponse acknowledges POST /pause.
+type PauseResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// ResumeResponse acknowledges POST /resume.
+type ResumeResponse struct {
+	Resumed bool `json:"resumed"`
+}
+
+// RetryRequest is sent to POST /retry. An empty URLs slice retries every known failure.
+type RetryRequest struct {
+	URLs []string `json:"urls,omitempty"`
+}
+
+// RetryResponse reports how many items were re-queued.
+type RetryResponse struct {
+	Requeued int `json:"requeued"`
+}
+
+// LogLevel mirrors the levels the Python pipeline already emits in its JSON-lines logs.
+type LogLevel string
+
+const (
+	LogDebug LogLevel = "debug"
+	LogInfo  LogLevel = "info"
+	LogWarn  LogLevel = "warn"
+	LogError LogLevel = "error"
+)
+
+// LogEntry is one record of the /logs Server-Sent Events stream.
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   LogLevel  `json:"level"`
+	Module  string    `json:"module"`
+	Message string    `json:"message"`
+}
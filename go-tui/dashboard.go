@@ -0,0 +1,228 @@
+package main
+
+/*
+Name:        go-tui/dashboard.go
+Date:        2025-10-07
+Script Name: dashboard.go
+Version:     1.2
+Log Summary: Multi-pane dashboard pieces for watch mode: source list items, the
+  discovered-URL table, a URLs/sec sparkline, and CSV/JSON export.
+Description: main.go owns the bubbletea model/Update wiring; this file holds the
+  view-model types and pure helpers the dashboard needs, so main.go doesn't balloon
+  into one file mixing program plumbing with rendering detail.
+Change Summary:
+  - 1.0 initial source list items, URL table rows, sparkline and export helpers.
+  - 1.1 help text now reflects that 'e' prompts for a filename instead of always
+    writing export.csv, so exportRecords' JSON branch is actually reachable.
+  - 1.2 sparkline scaled/clamped its index against len(blocks) (byte count of a
+    multi-byte-rune string) instead of the rune count, so any sample hitting the top
+    of the scale indexed the rune slice out of range; fixed to scale against
+    len(runes), the rune slice itself.
+*/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+
+	"github.com/cbwinslow/opengovt/usagecache"
+)
+
+// sourceItem adapts a usagecache.Entry to bubbles/list's list.Item (via
+// list.DefaultItem's Title/Description/FilterValue) for the left-hand source pane.
+type sourceItem usagecache.Entry
+
+func (s sourceItem) Title() string {
+	return s.Source
+}
+
+func (s sourceItem) Description() string {
+	return fmt.Sprintf("discovered=%d downloaded=%d failed=%d", s.Discovered, s.Downloaded, s.Failed)
+}
+
+func (s sourceItem) FilterValue() string {
+	return s.Source
+}
+
+func sourceItems(entries []usagecache.Entry) []list.Item {
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = sourceItem(e)
+	}
+	return items
+}
+
+// urlRecord is one row of the center pane: a discovered URL and its retry state.
+// It comes from retry_report.json, which still carries per-URL detail that the
+// aggregate usagecache intentionally doesn't (see usagecache's doc comment).
+type urlRecord struct {
+	Source    string `json:"source"`
+	URL       string `json:"url"`
+	Status    string `json:"status"`
+	Retries   int    `json:"retries"`
+	LastError string `json:"error"`
+}
+
+// loadURLRecords parses retry_report.json's "failures" array into urlRecords.
+func loadURLRecords(path string) []urlRecord {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		Failures []urlRecord `json:"failures"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil
+	}
+	for i := range doc.Failures {
+		if doc.Failures[i].Status == "" {
+			doc.Failures[i].Status = "failed"
+		}
+	}
+	return doc.Failures
+}
+
+var urlTableColumns = []table.Column{
+	{Title: "Source", Width: 16},
+	{Title: "URL", Width: 40},
+	{Title: "Status", Width: 8},
+	{Title: "Retries", Width: 8},
+	{Title: "Last Error", Width: 30},
+}
+
+func urlTableRows(records []urlRecord) []table.Row {
+	rows := make([]table.Row, len(records))
+	for i, r := range records {
+		rows[i] = table.Row{r.Source, r.URL, r.Status, strconv.Itoa(r.Retries), r.LastError}
+	}
+	return rows
+}
+
+func filterRecords(records []urlRecord, query string) []urlRecord {
+	if query == "" {
+		return records
+	}
+	q := strings.ToLower(query)
+	var out []urlRecord
+	for _, r := range records {
+		if strings.Contains(strings.ToLower(r.Source), q) || strings.Contains(strings.ToLower(r.URL), q) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// sparkline renders samples (e.g. URLs/sec over recent ticks) as a one-line block
+// bar chart, scaled to the highest sample in the window.
+func sparkline(samples []float64) string {
+	runes := []rune("▁▂▃▄▅▆▇█")
+	if len(samples) == 0 {
+		return ""
+	}
+	max := samples[0]
+	for _, s := range samples {
+		if s > max {
+			max = s
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	var b strings.Builder
+	for _, s := range samples {
+		idx := int((s / max) * float64(len(runes)-1))
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(runes) {
+			idx = len(runes) - 1
+		}
+		b.WriteRune(runes[idx])
+	}
+	return b.String()
+}
+
+// exportRecords writes records to path as CSV or JSON, chosen by the file extension.
+func exportRecords(path string, records []urlRecord) error {
+	if strings.HasSuffix(path, ".json") {
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, b, 0o644)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	if err := w.Write([]string{"source", "url", "status", "retries", "error"}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := w.Write([]string{r.Source, r.URL, r.Status, strconv.Itoa(r.Retries), r.LastError}); err != nil {
+			return err
+		}
+	}
+	return w.Error()
+}
+
+// joinHorizontal lays out panes side by side, padding each line to its pane's widest
+// line and separating panes with " | ", so rows of unequal line count still align.
+func joinHorizontal(panes ...string) string {
+	split := make([][]string, len(panes))
+	widths := make([]int, len(panes))
+	maxLines := 0
+	for i, p := range panes {
+		lines := strings.Split(p, "\n")
+		split[i] = lines
+		if len(lines) > maxLines {
+			maxLines = len(lines)
+		}
+		for _, l := range lines {
+			if len(l) > widths[i] {
+				widths[i] = len(l)
+			}
+		}
+	}
+
+	var out strings.Builder
+	for row := 0; row < maxLines; row++ {
+		for i, lines := range split {
+			line := ""
+			if row < len(lines) {
+				line = lines[row]
+			}
+			out.WriteString(line)
+			out.WriteString(strings.Repeat(" ", widths[i]-len(line)))
+			if i != len(split)-1 {
+				out.WriteString(" | ")
+			}
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+const helpText = `Keybindings:
+  /        filter the URL table by source or URL
+  enter    apply filter
+  esc      clear filter
+  up/down  move selection in the focused pane
+  tab      switch focus between the source list and URL table
+  r        retry the selected URL via the control API
+  e        prompt for a filename, then export the current table view
+           (.csv or .json, chosen by the extension you type)
+  ?        toggle this help
+  q/ctrl+c quit
+`
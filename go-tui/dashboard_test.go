@@ -0,0 +1,26 @@
+package main
+
+/*
+Name:        go-tui/dashboard_test.go
+Date:        2025-10-09
+Script Name: dashboard_test.go
+Version:     1.0
+Log Summary: Confirms sparkline never indexes its block-rune table out of range.
+Description: the block characters are multi-byte runes, so the byte length of their
+  string and the rune count differ; scaling/clamping against the byte length let
+  the highest sample in a window reach an out-of-range index. Feeds samples whose max
+  always maps to the top of the scale and checks sparkline doesn't panic.
+Change Summary:
+  - 1.0 initial regression test for the byte-vs-rune length bug.
+*/
+
+import "testing"
+
+func TestSparklineDoesNotPanicOnMaxSample(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3}); got == "" {
+		t.Fatal("sparkline returned empty string for non-empty samples")
+	}
+	if got := sparkline([]float64{5, 5, 5}); len([]rune(got)) != 3 {
+		t.Fatalf("sparkline: got %q, want 3 runes", got)
+	}
+}
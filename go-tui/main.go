@@ -4,116 +4,488 @@ package main
 Name:        Congress Bulk Pipeline TUI
 Date:        2025-10-02
 Script Name: main.go
-Version:     1.0
+Version:     1.7
 Log Summary: Bubble Tea-based TUI to monitor and control the Python pipeline.
 Description: The TUI supports two modes:
-  - watch: watch files (bulk_urls.json, retry_report.json, log directory) and show status
-  - http: talk to the pipeline HTTP control API for live commands and status
+  - watch: fsnotify-driven watch of the usagecache and logs/, rendered as a multi-pane
+    dashboard (sources, URL table, log tail + sparkline, command bar)
+  - http: talk to the pipeline's control package for live commands and status
+  A single context.Context, cancelled on Ctrl-C or SIGINT/SIGTERM, is threaded through
+  the program so the watcher goroutine and any in-flight HTTP calls stop deterministically.
 Change Summary:
   - 1.0 initial TUI with file-watch and HTTP control support
+  - 1.1 http mode now backed by the control package client instead of a bare URL flag;
+    added start/stop/pause/resume/retry keybindings and a bulk re-discovery trigger
+  - 1.2 watch mode replaced 2-3s full-file polling with watch.Watcher events and
+    tailer.Tailer, so large bulk_urls.json/log files are only re-read on actual writes
+  - 1.3 bulk_urls.json/retry_report.json replaced by the usagecache: the TUI now reads
+    only Stats() and a paginated Iter() instead of reparsing the raw JSON every reload
+  - 1.4 model carries a cancellable context shared with bubbletea via tea.WithContext,
+    so Ctrl-C and OS signals tear down the watcher and any pending requests together
+  - 1.5 watch mode is now a real dashboard: bubbles/list of sources, bubbles/table of
+    discovered URLs (filterable, retryable, exportable) and a bubbles/viewport log tail
+    with a URLs/sec sparkline, replacing the flat status string
+  - 1.6 'e' now prompts for a filename via a textinput before exporting, instead of
+    always writing export.csv, so exportRecords' CSV-vs-JSON dispatch is reachable
+  - 1.7 'q' and ctrl+c now emit QuitMsg instead of cancelling/quitting inline, so the
+    message the lifecycle doc promised is the actual shutdown path, not dead code
 Inputs: flags --mode (watch|http), --watch-dir, --api-url
 Outputs: interactive TUI for monitoring and starting/stopping tasks
 */
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cbwinslow/opengovt/control"
+	"github.com/cbwinslow/opengovt/tailer"
+	"github.com/cbwinslow/opengovt/usagecache"
+	"github.com/cbwinslow/opengovt/watch"
+)
+
+const watchDebounce = 250 * time.Millisecond
+
+// maxLogRecords bounds the in-memory structured log view.
+const maxLogRecords = 200
+
+// focusPane identifies which dashboard pane receives navigation keys.
+type focusPane int
+
+const (
+	focusSources focusPane = iota
+	focusURLs
 )
 
 type model struct {
 	mode       string
 	watchDir   string
 	apiURL     string
-	bulkURLs   []string
-	retryCount int
+	cacheStats usagecache.Stats
 	logTail    string
+	logRecords []tailer.Record
 	err        error
-	tick       <-chan time.Time
+	notice     string
+
+	client *control.Client
+	status control.StatusResponse
+
+	watcher *watch.Watcher
+	tail    *tailer.Tailer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	width, height int
+
+	sourceList     list.Model
+	urlTable       table.Model
+	filterInput    textinput.Model
+	exportInput    textinput.Model
+	logView        viewport.Model
+	filtering      bool
+	exporting      bool
+	showHelp       bool
+	focus          focusPane
+	urlRecords     []urlRecord
+	rateHistory    []float64
+	lastDiscovered int
+	lastSampleAt   time.Time
 }
 
-func readJSONLines(path string) ([]byte, error) {
-	return ioutil.ReadFile(path)
+// QuitMsg requests an orderly shutdown, equivalent to Ctrl-C: it cancels the shared
+// context (stopping the watcher and any in-flight requests) before quitting bubbletea.
+type QuitMsg struct{}
+
+// statusMsg carries a /status poll result into the Update loop.
+type statusMsg struct {
+	status control.StatusResponse
+	err    error
+}
+
+// actionMsg carries the result of a start/stop/pause/resume/retry command.
+type actionMsg struct {
+	label string
+	err   error
+}
+
+func (m model) pollStatus() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+		defer cancel()
+		st, err := m.client.Status(ctx)
+		return statusMsg{status: st, err: err}
+	}
 }
 
-func initialModel(mode, watchDir, apiURL string) model {
-	return model{mode: mode, watchDir: watchDir, apiURL: apiURL, tick: time.Tick(3 * time.Second)}
+func (m model) dispatch(label string, fn func(context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+		defer cancel()
+		return actionMsg{label: label, err: fn(ctx)}
+	}
+}
+
+func initialModel(ctx context.Context, cancel context.CancelFunc, mode, watchDir, apiURL string, watcher *watch.Watcher) model {
+	delegate := list.NewDefaultDelegate()
+	sourceList := list.New(nil, delegate, 30, 20)
+	sourceList.Title = "Sources"
+	sourceList.SetShowHelp(false)
+
+	urlTable := table.New(
+		table.WithColumns(urlTableColumns),
+		table.WithFocused(true),
+	)
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "filter by source or URL"
+
+	exportInput := textinput.New()
+	exportInput.Placeholder = "export filename (.csv or .json)"
+
+	m := model{
+		mode: mode, watchDir: watchDir, apiURL: apiURL,
+		tail: tailer.New(), ctx: ctx, cancel: cancel,
+		client:      control.NewClient(apiURL),
+		watcher:     watcher,
+		sourceList:  sourceList,
+		urlTable:    urlTable,
+		filterInput: filterInput,
+		exportInput: exportInput,
+		logView:     viewport.New(30, 10),
+	}
+	return m
+}
+
+// watchEventMsg carries one debounced filesystem change from the watch.Watcher.
+type watchEventMsg struct {
+	event watch.Event
+	ok    bool
+}
+
+func waitForWatchEvent(w *watch.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-w.Events()
+		return watchEventMsg{event: ev, ok: ok}
+	}
 }
 
 func (m model) Init() tea.Cmd {
-	// start periodic refresh
-	return tea.Tick(time.Second*1, func(t time.Time) tea.Msg { return t })
+	if m.mode == "http" {
+		return m.pollStatus()
+	}
+
+	if m.watcher == nil {
+		return func() tea.Msg { return statusMsg{err: fmt.Errorf("watch mode: no watcher configured")} }
+	}
+	m.watcher.Run(m.ctx)
+
+	// Prime the view with whatever is already on disk; fsnotify only fires on
+	// subsequent writes, not on pre-existing content.
+	return tea.Batch(
+		func() tea.Msg { return watchEventMsg{event: watch.Event{Path: m.cachePath()}, ok: true} },
+		func() tea.Msg { return watchEventMsg{event: watch.Event{Path: m.retryPath()}, ok: true} },
+		func() tea.Msg { return watchEventMsg{event: watch.Event{Path: m.logDir()}, ok: true} },
+		waitForWatchEvent(m.watcher),
+	)
+}
+
+func (m model) cachePath() string { return filepath.Join(m.watchDir, "usage.cache") }
+func (m model) retryPath() string { return filepath.Join(m.watchDir, "retry_report.json") }
+func (m model) logDir() string    { return filepath.Join(m.watchDir, "logs") }
+
+// latestLogFile returns the most recently modified file in logs/, if any.
+func (m model) latestLogFile() (string, bool) {
+	files, err := ioutil.ReadDir(m.logDir())
+	if err != nil || len(files) == 0 {
+		return "", false
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	return filepath.Join(m.logDir(), files[len(files)-1].Name()), true
+}
+
+// reloadCache reloads the usagecache, feeds every source into the left-hand list
+// (bubbles/list handles its own scrolling/paging), and samples a URLs/sec rate for
+// the sparkline. The TUI never reparses raw discovery JSON directly.
+func (m model) reloadCache() model {
+	c, err := usagecache.Load(m.cachePath())
+	if err != nil {
+		return m
+	}
+	stats := c.Stats()
+	m.sourceList.SetItems(sourceItems(c.Iter("")))
+
+	now := time.Now()
+	if !m.lastSampleAt.IsZero() {
+		if dt := now.Sub(m.lastSampleAt).Seconds(); dt > 0 {
+			rate := float64(stats.Discovered-m.lastDiscovered) / dt
+			if rate < 0 {
+				rate = 0
+			}
+			m.rateHistory = append(m.rateHistory, rate)
+			if len(m.rateHistory) > 30 {
+				m.rateHistory = m.rateHistory[len(m.rateHistory)-30:]
+			}
+		}
+	}
+	m.lastDiscovered = stats.Discovered
+	m.lastSampleAt = now
+	m.cacheStats = stats
+	return m
 }
 
-type tickMsg time.Time
+// reloadURLRecords reloads retry_report.json's per-URL detail and refreshes the
+// center table, respecting any active filter.
+func (m model) reloadURLRecords() model {
+	m.urlRecords = loadURLRecords(m.retryPath())
+	m.urlTable.SetRows(urlTableRows(filterRecords(m.urlRecords, m.filterInput.Value())))
+	return m
+}
+
+func (m model) reloadLogs() model {
+	path, ok := m.latestLogFile()
+	if !ok {
+		return m
+	}
+	recs, err := m.tail.Poll(path)
+	if err != nil || len(recs) == 0 {
+		return m
+	}
+	m.logRecords = append(m.logRecords, recs...)
+	if len(m.logRecords) > maxLogRecords {
+		m.logRecords = m.logRecords[len(m.logRecords)-maxLogRecords:]
+	}
+	var tail strings.Builder
+	for _, r := range m.logRecords {
+		fmt.Fprintf(&tail, "[%s] %s: %s\n", r.Level, r.Module, r.Message)
+	}
+	m.logTail = tail.String()
+	m.logView.SetContent(m.logTail)
+	m.logView.GotoBottom()
+	return m
+}
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case time.Time:
-		// refresh files
-		if m.mode == "watch" {
-			bulkPath := filepath.Join(m.watchDir, "bulk_urls.json")
-			retryPath := filepath.Join(m.watchDir, "retry_report.json")
-			if data, err := readJSONLines(bulkPath); err == nil {
-				var d map[string]interface{}
-				_ = json.Unmarshal(data, &d)
-				if agg, ok := d["aggregate_urls"].([]interface{}); ok {
-					m.bulkURLs = nil
-					for _, u := range agg {
-						m.bulkURLs = append(m.bulkURLs, fmt.Sprintf("%v", u))
-					}
-				}
+	case statusMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.err = nil
+			m.status = msg.status
+		}
+		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return m.pollStatus()() })
+	case actionMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("%s: %w", msg.label, msg.err)
+		}
+		return m, m.pollStatus()
+	case QuitMsg:
+		m.cancel()
+		return m, tea.Quit
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		paneHeight := msg.Height - 8
+		if paneHeight < 3 {
+			paneHeight = 3
+		}
+		leftW, centerW := msg.Width/4, msg.Width/2
+		rightW := msg.Width - leftW - centerW - 4
+		m.sourceList.SetSize(leftW, paneHeight)
+		m.urlTable.SetWidth(centerW)
+		m.urlTable.SetHeight(paneHeight)
+		m.logView.Width, m.logView.Height = rightW, paneHeight
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, func() tea.Msg { return QuitMsg{} }
+		}
+		if m.mode == "watch" && m.filtering {
+			switch msg.String() {
+			case "enter":
+				m.filtering = false
+				return m, nil
+			case "esc":
+				m.filtering = false
+				m.filterInput.SetValue("")
+				m.urlTable.SetRows(urlTableRows(m.urlRecords))
+				return m, nil
 			}
-			if data, err := readJSONLines(retryPath); err == nil {
-				var d map[string]interface{}
-				_ = json.Unmarshal(data, &d)
-				if f, ok := d["failures"].([]interface{}); ok {
-					m.retryCount = len(f)
+			var cmd tea.Cmd
+			m.filterInput, cmd = m.filterInput.Update(msg)
+			m.urlTable.SetRows(urlTableRows(filterRecords(m.urlRecords, m.filterInput.Value())))
+			return m, cmd
+		}
+		if m.mode == "watch" && m.exporting {
+			switch msg.String() {
+			case "enter":
+				m.exporting = false
+				path := m.exportInput.Value()
+				rows := filterRecords(m.urlRecords, m.filterInput.Value())
+				if err := exportRecords(path, rows); err != nil {
+					m.notice = fmt.Sprintf("export failed: %v", err)
+				} else {
+					m.notice = fmt.Sprintf("exported %d rows to %s", len(rows), path)
 				}
+				return m, nil
+			case "esc":
+				m.exporting = false
+				return m, nil
 			}
-			// tail logs (last 10 lines)
-			logPath := filepath.Join(m.watchDir, "logs")
-			files, _ := ioutil.ReadDir(logPath)
-			if len(files) > 0 {
-				latest := files[len(files)-1]
-				lp := filepath.Join(logPath, latest.Name())
-				if b, err := ioutil.ReadFile(lp); err == nil {
-					txt := string(b)
-					// naive tail
-					if len(txt) > 2000 {
-						txt = txt[len(txt)-2000:]
-					}
-					m.logTail = txt
+			var cmd tea.Cmd
+			m.exportInput, cmd = m.exportInput.Update(msg)
+			return m, cmd
+		}
+		if msg.String() == "q" {
+			return m, func() tea.Msg { return QuitMsg{} }
+		}
+		if m.mode == "watch" {
+			switch msg.String() {
+			case "/":
+				m.filtering = true
+				m.filterInput.Focus()
+				return m, nil
+			case "?":
+				m.showHelp = !m.showHelp
+				return m, nil
+			case "tab":
+				if m.focus == focusSources {
+					m.focus = focusURLs
+				} else {
+					m.focus = focusSources
+				}
+				return m, nil
+			case "r":
+				if row := m.urlTable.SelectedRow(); len(row) > 1 {
+					url := row[1]
+					return m, m.dispatch("retry", func(ctx context.Context) error {
+						_, err := m.client.Retry(ctx, control.RetryRequest{URLs: []string{url}})
+						return err
+					})
 				}
+				return m, nil
+			case "e":
+				m.exporting = true
+				m.exportInput.SetValue("export.csv")
+				m.exportInput.Focus()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			if m.focus == focusSources {
+				m.sourceList, cmd = m.sourceList.Update(msg)
+			} else {
+				m.urlTable, cmd = m.urlTable.Update(msg)
 			}
+			return m, cmd
+		}
+		if m.mode != "http" {
+			break
+		}
+		switch msg.String() {
+		case "s":
+			return m, m.dispatch("start", func(ctx context.Context) error {
+				_, err := m.client.Start(ctx, control.StartRequest{})
+				return err
+			})
+		case "d":
+			// bulk-URL re-discovery: a start request with no sources scopes to everything.
+			return m, m.dispatch("rediscover", func(ctx context.Context) error {
+				_, err := m.client.Start(ctx, control.StartRequest{Sources: nil})
+				return err
+			})
+		case "x":
+			return m, m.dispatch("stop", func(ctx context.Context) error {
+				_, err := m.client.Stop(ctx, control.StopRequest{})
+				return err
+			})
+		case "p":
+			return m, m.dispatch("pause", func(ctx context.Context) error {
+				_, err := m.client.Pause(ctx)
+				return err
+			})
+		case "u":
+			return m, m.dispatch("resume", func(ctx context.Context) error {
+				_, err := m.client.Resume(ctx)
+				return err
+			})
+		case "r":
+			return m, m.dispatch("retry", func(ctx context.Context) error {
+				_, err := m.client.Retry(ctx, control.RetryRequest{})
+				return err
+			})
 		}
-		return m, tea.Tick(3 * time.Second, func(t time.Time) tea.Msg { return t })
+		return m, nil
+	case watchEventMsg:
+		if !msg.ok {
+			// fsnotify watcher closed (e.g. ctx cancelled); stop polling for events.
+			return m, nil
+		}
+		switch {
+		case msg.event.Path == m.cachePath():
+			m = m.reloadCache()
+		case msg.event.Path == m.retryPath():
+			m = m.reloadURLRecords()
+		case filepath.Dir(msg.event.Path) == m.logDir() || msg.event.Path == m.logDir():
+			m = m.reloadLogs()
+		}
+		return m, waitForWatchEvent(m.watcher)
 	}
 	return m, nil
 }
 
 func (m model) View() string {
+	if m.mode == "watch" && m.showHelp {
+		return helpText + "\n(press ? to return to the dashboard)\n"
+	}
+
 	s := "Congress Bulk Pipeline TUI\n\n"
-	s += fmt.Sprintf("Mode: %s\n\n", m.mode)
 	if m.mode == "watch" {
-		s += fmt.Sprintf("Discovered URLs: %d\n", len(m.bulkURLs))
-		s += fmt.Sprintf("Retry failures: %d\n\n", m.retryCount)
-		s += "Sample discovered URLs:\n"
-		for i, u := range m.bulkURLs {
-			if i >= 10 {
-				break
-			}
-			s += fmt.Sprintf(" - %s\n", u)
+		s += fmt.Sprintf("Sources: %d  Discovered: %d  Downloaded: %d  Retried: %d  Failed: %d  Rate: %s\n\n",
+			m.cacheStats.Sources, m.cacheStats.Discovered, m.cacheStats.Downloaded,
+			m.cacheStats.Retried, m.cacheStats.Failed, sparkline(m.rateHistory))
+
+		right := "Log tail:\n" + m.logView.View()
+		s += joinHorizontal(m.sourceList.View(), m.urlTable.View(), right)
+
+		s += "\n"
+		switch {
+		case m.filtering:
+			s += "filter: " + m.filterInput.View() + "\n"
+		case m.exporting:
+			s += "export to (.csv or .json): " + m.exportInput.View() + "\n"
+		default:
+			s += "[/]filter [tab]focus [r]etry [e]xport [?]help [q]uit\n"
+		}
+		if m.notice != "" {
+			s += m.notice + "\n"
+		}
+	}
+	if m.mode == "http" {
+		s += fmt.Sprintf("API: %s\n\n", m.apiURL)
+		s += fmt.Sprintf("State: %s\n", m.status.State)
+		s += fmt.Sprintf("Discovered: %d  Downloaded: %d  Failed: %d\n",
+			m.status.DiscoveredURLs, m.status.Downloaded, m.status.Failed)
+		if m.status.LastError != "" {
+			s += fmt.Sprintf("Last error: %s\n", m.status.LastError)
 		}
-		s += "\nLog tail (last chunk):\n"
-		s += m.logTail + "\n"
+		s += "\n[s]tart  [d]iscover  [x]stop  [p]ause  res[u]me  [r]etry\n"
+	}
+	if m.err != nil {
+		s += fmt.Sprintf("\nerror: %v\n", m.err)
 	}
 	s += "\nPress Ctrl-C to quit.\n"
 	return s
@@ -121,12 +493,31 @@ func (m model) View() string {
 
 func main() {
 	mode := flag.String("mode", "watch", "mode: watch or http")
-	watchDir := flag.String("watch-dir", ".", "directory to watch for bulk_urls.json and retry_report.json")
+	watchDir := flag.String("watch-dir", ".", "directory containing usage.cache and logs/ to watch")
 	apiURL := flag.String("api-url", "http://localhost:8080", "HTTP control API URL (when mode=http)")
 	flag.Parse()
 
-	m := initialModel(*mode, *watchDir, *apiURL)
-	p := tea.NewProgram(m)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	var watcher *watch.Watcher
+	if *mode != "http" {
+		w, err := watch.New(*watchDir, watchDebounce)
+		if err != nil {
+			fmt.Println("Error starting watcher:", err)
+			os.Exit(1)
+		}
+		watcher = w
+	}
+
+	m := initialModel(ctx, cancel, *mode, *watchDir, *apiURL, watcher)
+	p := tea.NewProgram(m, tea.WithContext(ctx))
 	if err := p.Start(); err != nil {
 		fmt.Println("Error running TUI:", err)
 		os.Exit(1)
@@ -0,0 +1,116 @@
+package tailer
+
+/*
+Name:        tailer/tailer.go
+Date:        2025-10-04
+Script Name: tailer.go
+Version:     1.0
+Log Summary: Line-oriented, rotation-safe tailer for the pipeline's JSON-lines logs.
+Description: Tracks each watched file by (device, inode) plus a byte offset, so it can
+  tell a rotated file (new inode at the same path) from one that simply grew, and never
+  re-emits a line it already returned. Replaces the naive "read whole file, slice the
+  last 2KB" approach, which re-read the entire file every tick and could split a record
+  mid-line.
+Change Summary:
+  - 1.0 initial inode-tracked tailer with JSON-lines parsing.
+*/
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Record is one parsed JSON-lines log entry.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Module  string    `json:"module"`
+	Message string    `json:"message"`
+}
+
+// fileState is what Tailer remembers about a path between calls to Poll.
+type fileState struct {
+	dev, ino uint64
+	offset   int64
+}
+
+// Tailer incrementally reads new lines appended to a set of files, handling rotation.
+type Tailer struct {
+	states map[string]fileState
+}
+
+// New returns an empty Tailer; state accumulates as Poll is called per path.
+func New() *Tailer {
+	return &Tailer{states: map[string]fileState{}}
+}
+
+// Poll reads any bytes appended to path since the last call and returns the fully
+// parsed Records. A rotated file (path reused with a new inode) is read from its start.
+// A line that fails to parse as JSON is skipped rather than failing the whole poll,
+// since a writer can be interrupted mid-line.
+func (t *Tailer) Poll(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			delete(t.states, path)
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	var dev, ino uint64
+	if ok {
+		dev, ino = uint64(stat.Dev), stat.Ino
+	}
+
+	prev, seen := t.states[path]
+	offset := int64(0)
+	if seen && prev.dev == dev && prev.ino == ino {
+		if info.Size() < prev.offset {
+			// Truncated in place (logrotate "copytruncate" style); restart from 0.
+			offset = 0
+		} else {
+			offset = prev.offset
+		}
+	}
+
+	if _, err := f.Seek(offset, os.SEEK_SET); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	reader := bufio.NewReaderSize(f, 64*1024)
+	read := offset
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			// Only a newline-terminated line is a complete record; advance the
+			// offset past it. A trailing partial line (writer interrupted
+			// mid-line) is left unconsumed so the next Poll re-reads it whole.
+			read += int64(len(line))
+			var rec Record
+			if jsonErr := json.Unmarshal(line[:len(line)-1], &rec); jsonErr == nil {
+				records = append(records, rec)
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				return records, err
+			}
+			break
+		}
+	}
+
+	t.states[path] = fileState{dev: dev, ino: ino, offset: read}
+	return records, nil
+}
@@ -0,0 +1,56 @@
+package tailer
+
+/*
+Name:        tailer/tailer_test.go
+Date:        2025-10-07
+Script Name: tailer_test.go
+Version:     1.0
+Log Summary: Confirms Poll never re-emits or drops a record across an unterminated
+  trailing line.
+Description: Writes a record with no trailing newline (a writer interrupted mid-line),
+  polls, then appends the newline and the next record, and checks the partial line is
+  read exactly once, whole, on the following Poll instead of being duplicated or lost.
+Change Summary:
+  - 1.0 initial regression test for the offset-overrun bug.
+*/
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPollHandlesUnterminatedTrailingLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(`{"level":"info","module":"a","message":"first"}`+"\n"+`{"level":"info","module":"a","message":"partial"`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tl := New()
+	recs, err := tl.Poll(path)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Message != "first" {
+		t.Fatalf("first Poll: got %+v, want one record with message %q", recs, "first")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if _, err := f.WriteString("}\n" + `{"level":"info","module":"a","message":"third"}` + "\n"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	recs, err = tl.Poll(path)
+	if err != nil {
+		t.Fatalf("second Poll: %v", err)
+	}
+	if len(recs) != 2 || recs[0].Message != "partial" || recs[1].Message != "third" {
+		t.Fatalf("second Poll: got %+v, want [partial, third] with no duplicates", recs)
+	}
+}
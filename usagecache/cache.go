@@ -0,0 +1,196 @@
+package usagecache
+
+/*
+Name:        usagecache/cache.go
+Date:        2025-10-05
+Script Name: cache.go
+Version:     1.1
+Log Summary: On-disk, incrementally-updated cache of per-source discovery/retry counters.
+Description: Inspired by minio's data-usage-cache redesign: rather than reparsing
+  bulk_urls.json (and retry_report.json) into a flat []string every tick, the pipeline
+  keeps one Entry per discovery source with running counters, gob-encodes the whole
+  thing to disk, and writes small deltas between full saves. A periodic Compact merges
+  pending deltas into the base file so the working set stays a handful of files instead
+  of growing without bound. Callers outside this package should only need Load, Save,
+  Merge, Iter and Stats.
+Change Summary:
+  - 1.0 initial gob-encoded cache with delta compaction.
+  - 1.1 Load now folds in pending delta files too (read-only), so Stats/Iter are
+    current between compactions instead of lagging until the next Compact.
+*/
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Entry holds the running counters for one discovery source.
+type Entry struct {
+	Source     string
+	Discovered int
+	Downloaded int
+	Retried    int
+	Failed     int
+	Bytes      int64
+}
+
+func (e Entry) merge(o Entry) Entry {
+	e.Discovered += o.Discovered
+	e.Downloaded += o.Downloaded
+	e.Retried += o.Retried
+	e.Failed += o.Failed
+	e.Bytes += o.Bytes
+	return e
+}
+
+// Stats is the aggregate view of every Entry in a Cache, used by the TUI's status pane.
+type Stats struct {
+	Sources    int
+	Discovered int
+	Downloaded int
+	Retried    int
+	Failed     int
+	Bytes      int64
+}
+
+// Cache is a concurrency-safe, gob-encoded set of per-source Entries backed by a file.
+type Cache struct {
+	mu      sync.RWMutex
+	path    string
+	entries map[string]Entry
+}
+
+// New returns an empty Cache that will (Save) to path.
+func New(path string) *Cache {
+	return &Cache{path: path, entries: map[string]Entry{}}
+}
+
+// Load reads a gob-encoded Cache from path and folds in every pending delta file found
+// alongside it (oldest first), so counts written since the last Compact are visible
+// immediately instead of only after the next compaction. A missing base file yields an
+// empty Cache, matching the common "first run" case rather than forcing every caller to
+// check os.IsNotExist. Unlike Compact, Load never writes or removes anything.
+func Load(path string) (*Cache, error) {
+	c, err := loadBase(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), deltaPattern))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	for _, dp := range matches {
+		delta, err := loadBase(dp)
+		if err != nil {
+			continue // a partially-written delta shouldn't hide the rest of the cache
+		}
+		c.Merge(delta)
+	}
+	return c, nil
+}
+
+// loadBase reads a single gob-encoded Cache file verbatim, without folding in any
+// sibling delta files. Compact uses this directly to avoid merging deltas twice.
+func loadBase(path string) (*Cache, error) {
+	c := New(path)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	if err := gob.NewDecoder(f).Decode(&c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save atomically rewrites the full cache to its path (write to a temp file, then
+// rename), so a reader never observes a half-written cache.
+func (c *Cache) Save() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.saveLocked(c.path)
+}
+
+func (c *Cache) saveLocked(path string) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c.entries); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Put upserts the Entry for source, replacing its counters outright. Callers that want
+// to add to existing counters should read via Iter/Stats first, or use Merge.
+func (c *Cache) Put(e Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[e.Source] = e
+}
+
+// Add increments source's counters by the given deltas, creating the Entry if absent.
+func (c *Cache) Add(source string, delta Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delta.Source = source
+	c.entries[source] = c.entries[source].merge(delta)
+}
+
+// Merge folds other's entries additively into c, summing counters for sources present
+// in both. This is how delta caches are combined with the base cache during Compact.
+func (c *Cache) Merge(other *Cache) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for source, e := range other.entries {
+		c.entries[source] = c.entries[source].merge(e)
+	}
+}
+
+// Iter returns every Entry whose Source starts with prefix, sorted by Source, so the
+// TUI can page through results deterministically without loading the whole cache twice.
+func (c *Cache) Iter(prefix string) []Entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]Entry, 0, len(c.entries))
+	for source, e := range c.entries {
+		if strings.HasPrefix(source, prefix) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+// Stats returns the aggregate totals across every source in the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := Stats{Sources: len(c.entries)}
+	for _, e := range c.entries {
+		s.Discovered += e.Discovered
+		s.Downloaded += e.Downloaded
+		s.Retried += e.Retried
+		s.Failed += e.Failed
+		s.Bytes += e.Bytes
+	}
+	return s
+}
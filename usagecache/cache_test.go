@@ -0,0 +1,61 @@
+package usagecache
+
+/*
+Name:        usagecache/cache_test.go
+Date:        2025-10-08
+Script Name: cache_test.go
+Version:     1.0
+Log Summary: Confirms Load reflects pending deltas without touching them, and Compact
+  doesn't double-count a delta it has already folded in.
+Description: Writes a base cache plus one delta file and checks Load's Stats include
+  both and the delta file is left on disk; then runs Compact and checks the merged
+  total is still correct (not doubled) and the delta file is gone.
+Change Summary:
+  - 1.0 initial regression test for the stale-Load-ignores-deltas bug.
+*/
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFoldsInPendingDeltas(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "usage.cache")
+
+	base := New(basePath)
+	base.Put(Entry{Source: "a", Discovered: 10})
+	if err := base.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	delta := New(basePath)
+	delta.Put(Entry{Source: "a", Discovered: 4})
+	deltaPath, err := delta.SaveDelta(delta.Iter(""))
+	if err != nil {
+		t.Fatalf("SaveDelta: %v", err)
+	}
+
+	loaded, err := Load(basePath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := loaded.Stats().Discovered; got != 14 {
+		t.Errorf("Load: got Discovered=%d, want 14 (base + pending delta)", got)
+	}
+	if _, err := os.Stat(deltaPath); err != nil {
+		t.Errorf("Load must not remove pending delta files, but stat failed: %v", err)
+	}
+
+	compacted, err := Compact(basePath)
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if got := compacted.Stats().Discovered; got != 14 {
+		t.Errorf("Compact: got Discovered=%d, want 14 (no double-count of the delta)", got)
+	}
+	if _, err := os.Stat(deltaPath); !os.IsNotExist(err) {
+		t.Errorf("Compact should have removed the folded delta file, stat err = %v", err)
+	}
+}
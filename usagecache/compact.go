@@ -0,0 +1,79 @@
+package usagecache
+
+/*
+Name:        usagecache/compact.go
+Date:        2025-10-05
+Script Name: compact.go
+Version:     1.0
+Log Summary: Delta-file writing and periodic compaction for the usagecache.
+Description: The pipeline writes one small delta file per cycle instead of rewriting
+  the whole base cache; Compact folds every pending delta into the base cache in
+  filename order and removes the delta files once merged in, so the on-disk footprint
+  stays bounded regardless of how often deltas are written.
+Change Summary:
+  - 1.0 initial delta file naming and compaction.
+*/
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// deltaPattern matches delta files written by SaveDelta, in the same directory as the
+// base cache.
+const deltaPattern = "delta-*.cache"
+
+// SaveDelta writes only the given entries as a new delta file alongside the base
+// cache, named so Compact can find and order them. It does not touch the base file.
+func (c *Cache) SaveDelta(entries []Entry) (string, error) {
+	delta := New("")
+	for _, e := range entries {
+		delta.entries[e.Source] = e
+	}
+	name := fmt.Sprintf("delta-%020d.cache", deltaStamp())
+	path := filepath.Join(filepath.Dir(c.path), name)
+	if err := delta.saveLocked(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// deltaStamp is split out so tests can override it; production uses the wall clock.
+var deltaStamp = func() int64 { return time.Now().UnixNano() }
+
+// Compact loads the base cache at basePath, merges every pending delta file found in
+// the same directory (oldest first), writes the merged result back to basePath, and
+// removes the delta files that were folded in. It returns the compacted Cache.
+func Compact(basePath string) (*Cache, error) {
+	base, err := loadBase(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(basePath), deltaPattern))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	var merged []string
+	for _, path := range matches {
+		delta, err := loadBase(path)
+		if err != nil {
+			continue // a partially-written delta shouldn't block compaction of the rest
+		}
+		base.Merge(delta)
+		merged = append(merged, path)
+	}
+
+	if err := base.Save(); err != nil {
+		return nil, err
+	}
+	for _, path := range merged {
+		_ = os.Remove(path)
+	}
+	return base, nil
+}
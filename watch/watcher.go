@@ -0,0 +1,168 @@
+package watch
+
+/*
+Name:        watch/watcher.go
+Date:        2025-10-04
+Script Name: watcher.go
+Version:     1.1
+Log Summary: fsnotify-backed recursive directory watcher with debounced change events.
+Description: Wraps fsnotify.Watcher to watch a root directory and every subdirectory
+  beneath it (in particular logs/, which grows new files at runtime), and collapses
+  bursts of writes to the same path into a single debounced Event. Replaces the
+  previous approach of re-reading bulk_urls.json / retry_report.json on a fixed tick.
+Change Summary:
+  - 1.0 initial recursive watcher with debouncing.
+  - 1.1 Run is now context-driven end to end: the debounce timer is built through an
+    injectable newTimer so tests can drive it with a fake clock, and Wait lets callers
+    (and tests) confirm the goroutine has actually exited before asserting no leaks.
+*/
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Event is a debounced filesystem change under the watched root.
+type Event struct {
+	Path string
+	Op   fsnotify.Op
+}
+
+// Watcher recursively watches a directory tree and emits debounced Events on Events().
+type Watcher struct {
+	root     string
+	debounce time.Duration
+	fsw      *fsnotify.Watcher
+	events   chan Event
+	errs     chan error
+	done     chan struct{}
+
+	// newTimer returns a channel that fires after d, and a stop func to release it
+	// early. It is a field (not a bare time.NewTimer call) so tests can substitute a
+	// fake clock instead of waiting out real debounce windows.
+	newTimer func(d time.Duration) (<-chan time.Time, func())
+}
+
+// New creates a Watcher rooted at dir, recursively adding every existing subdirectory.
+// debounce controls how long to coalesce repeated events for the same path (e.g. the
+// many small writes a log rotation or a bulk JSON rewrite produces).
+func New(dir string, debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		root:     dir,
+		debounce: debounce,
+		fsw:      fsw,
+		events:   make(chan Event),
+		errs:     make(chan error, 1),
+		done:     make(chan struct{}),
+		newTimer: realTimer,
+	}
+	if err := w.addRecursive(dir); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func realTimer(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTimer(d)
+	return t.C, func() { t.Stop() }
+}
+
+func (w *Watcher) addRecursive(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// A subdirectory may disappear between Walk listing it and us adding it
+			// (e.g. logs/ rotation); skip rather than failing the whole watch setup.
+			return nil
+		}
+		if info.IsDir() {
+			return w.fsw.Add(path)
+		}
+		return nil
+	})
+}
+
+// Run starts the debouncing goroutine. It exits when ctx is cancelled, closing the
+// underlying fsnotify watcher and the Events/Errors channels. Call Wait to block until
+// the goroutine has actually exited.
+func (w *Watcher) Run(ctx context.Context) {
+	go func() {
+		defer close(w.done)
+		defer close(w.errs)
+		defer close(w.events)
+		defer w.fsw.Close()
+
+		pending := map[string]Event{}
+		var flush <-chan time.Time
+		var stopTimer func()
+
+		schedule := func() {
+			if stopTimer != nil {
+				stopTimer()
+			}
+			flush, stopTimer = w.newTimer(w.debounce)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				if stopTimer != nil {
+					stopTimer()
+				}
+				return
+			case ev, ok := <-w.fsw.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						_ = w.addRecursive(ev.Name)
+					}
+				}
+				pending[ev.Name] = Event{Path: ev.Name, Op: ev.Op}
+				schedule()
+			case <-flush:
+				for _, e := range pending {
+					select {
+					case w.events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				pending = map[string]Event{}
+				flush, stopTimer = nil, nil
+			case err, ok := <-w.fsw.Errors:
+				if !ok {
+					return
+				}
+				select {
+				case w.errs <- err:
+				default:
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until the Run goroutine has exited, e.g. after its context is cancelled.
+func (w *Watcher) Wait() {
+	<-w.done
+}
+
+// Events returns the channel of debounced filesystem changes.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors returns the channel of underlying fsnotify errors.
+func (w *Watcher) Errors() <-chan error {
+	return w.errs
+}
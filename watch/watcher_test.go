@@ -0,0 +1,89 @@
+package watch
+
+/*
+Name:        watch/watcher_test.go
+Date:        2025-10-06
+Script Name: watcher_test.go
+Version:     1.0
+Log Summary: Confirms Run shuts down deterministically on context cancellation.
+Description: Uses a fake debounce clock (so the test never waits out a real timer) and
+  a synthetic fsnotify event to drive the goroutine into its debounce-scheduled state,
+  then cancels the context and asserts the timer is stopped and the goroutine exits.
+Change Summary:
+  - 1.0 initial shutdown test.
+*/
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestRunExitsOnContextCancel(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	fakeTimerCh := make(chan time.Time) // never fires: proves shutdown doesn't depend on it
+	var stopped bool
+	w.newTimer = func(time.Duration) (<-chan time.Time, func()) {
+		return fakeTimerCh, func() { stopped = true }
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Run(ctx)
+
+	// Feed a synthetic fsnotify event so the goroutine schedules its debounce timer
+	// via the fake clock above, rather than racing a real filesystem write.
+	w.fsw.Events <- fsnotify.Event{Name: filepath.Join(dir, "f"), Op: fsnotify.Write}
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not exit after context cancellation")
+	}
+
+	if !stopped {
+		t.Error("debounce timer was not stopped on shutdown")
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Error("expected Events channel to be closed after shutdown")
+	}
+}
+
+func TestRunExitsWithoutPendingTimer(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir, time.Millisecond)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Run(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not exit for an idle watcher with a cancelled context")
+	}
+}